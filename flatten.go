@@ -0,0 +1,153 @@
+package xlsx_tags
+
+// Flattening of nested and embedded structs into sheet columns, used by
+// the write path (writeWithTags, WriteToSheetWithOptions). An embedded
+// (anonymous) struct's tagged fields are merged inline. A named nested
+// struct field tagged with its own heading contributes a block of
+// columns prefixed with "<heading>NestedSeparator", ordered after
+// parent.order*1000. For a struct nested directly in the item type the
+// block stays contiguous and stable relative to sibling top-level
+// columns. A struct nested inside a nested struct adds its own
+// order*1000 to the enclosing prefix, so its columns may sort apart from
+// the rest of the enclosing block.
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// NestedSeparator joins a parent nested-struct heading to its children's
+// headings, e.g. heading "Customer" with child heading "Name" becomes
+// "Customer / Name".
+var NestedSeparator = " / "
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// fieldSpec binds a resolved column (order, heading and the rest of its
+// parseOpts) to the reflect field-index path used to reach its value,
+// which may descend through embedded or pointer-to-struct fields.
+type fieldSpec struct {
+	opts parseOpts
+	path []int
+}
+
+// getFieldSpecs walks itemType recursively and returns one fieldSpec per
+// leaf column, sorted by composed order.
+func getFieldSpecs(itemType reflect.Type) ([]fieldSpec, error) {
+	specs, err := collectFieldSpecs(itemType, nil, "", 0, map[reflect.Type]bool{})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(specs, func(i, j int) bool {
+		return specs[i].opts.order < specs[j].opts.order
+	})
+	return specs, nil
+}
+
+func collectFieldSpecs(t reflect.Type, pathPrefix []int, headingPrefix string, orderPrefix int, ancestors map[reflect.Type]bool) ([]fieldSpec, error) {
+	if ancestors[t] {
+		return nil, fmt.Errorf("xlsx_tags: cycle detected while flattening type %s", t)
+	}
+	ancestors[t] = true
+	defer delete(ancestors, t)
+
+	var specs []fieldSpec
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		path := append(append([]int{}, pathPrefix...), i)
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		if field.Anonymous && fieldType.Kind() == reflect.Struct && fieldType != timeType {
+			nested, err := collectFieldSpecs(fieldType, path, headingPrefix, orderPrefix, ancestors)
+			if err != nil {
+				return nil, err
+			}
+			specs = append(specs, nested...)
+			continue
+		}
+
+		tag := field.Tag.Get(tagName)
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		opt, err := parseOptFromTag(tag)
+		if err != nil {
+			return nil, err
+		}
+
+		if fieldType.Kind() == reflect.Struct && fieldType != timeType {
+			nested, err := collectFieldSpecs(fieldType, path, headingPrefix+opt.heading+NestedSeparator, orderPrefix+opt.order*1000, ancestors)
+			if err != nil {
+				return nil, err
+			}
+			specs = append(specs, nested...)
+			continue
+		}
+
+		opt.heading = headingPrefix + opt.heading
+		opt.order = orderPrefix + opt.order
+		specs = append(specs, fieldSpec{opts: opt, path: path})
+	}
+	return specs, nil
+}
+
+// fieldByPath follows path from v, dereferencing pointers along the way.
+// It reports false if any pointer on the path is nil, meaning the column
+// should be written as an empty cell.
+func fieldByPath(v reflect.Value, path []int) (reflect.Value, bool) {
+	for _, idx := range path {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(idx)
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	return v, true
+}
+
+// fieldForSet follows path from v, allocating any nil pointer it passes
+// through (including a pointer-typed leaf field) so the leaf is
+// addressable and settable. It is the write-side counterpart of
+// fieldByPath, used by ReadFromSheet to populate the same flattened
+// nested/embedded fields writeWithTags reads from.
+func fieldForSet(v reflect.Value, path []int) reflect.Value {
+	for _, idx := range path {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(idx)
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func optsFromSpecs(specs []fieldSpec) []parseOpts {
+	opts := make([]parseOpts, len(specs))
+	for i, spec := range specs {
+		opts[i] = spec.opts
+	}
+	return opts
+}
@@ -0,0 +1,106 @@
+package xlsx_tags
+
+import (
+	"github.com/tealeg/xlsx"
+	"reflect"
+	"testing"
+)
+
+type flattenAddress struct {
+	City string `xls:"order=1,heading=City"`
+}
+
+type flattenCustomer struct {
+	Name    string          `xls:"order=1,heading=Name"`
+	Address *flattenAddress `xls:"order=2,heading=Address"`
+}
+
+type flattenOrder struct {
+	flattenBase
+	ID       int             `xls:"order=1,heading=ID"`
+	Customer flattenCustomer `xls:"order=2,heading=Customer"`
+}
+
+type flattenBase struct {
+	Source string `xls:"order=3,heading=Source"`
+}
+
+func TestWriteWithTagsFlattensNestedStructs(t *testing.T) {
+	orders := []flattenOrder{
+		{
+			flattenBase: flattenBase{Source: "web"},
+			ID:          1,
+			Customer: flattenCustomer{
+				Name:    "Ada",
+				Address: &flattenAddress{City: "London"},
+			},
+		},
+		{
+			flattenBase: flattenBase{Source: "phone"},
+			ID:          2,
+			Customer: flattenCustomer{
+				Name:    "Grace",
+				Address: nil,
+			},
+		},
+	}
+
+	file := xlsx.NewFile()
+	sheet, err := file.AddSheet("Orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeWithTags(sheet, orders); err != nil {
+		t.Fatal(err)
+	}
+
+	heading := sheet.Rows[0]
+	colOf := make(map[string]int, len(heading.Cells))
+	for i, cell := range heading.Cells {
+		colOf[cell.Value] = i
+	}
+	for _, want := range []string{"ID", "Source", "Customer / Name", "Customer / Address / City"} {
+		if _, ok := colOf[want]; !ok {
+			t.Fatalf("missing heading %q, got %v", want, heading.Cells)
+		}
+	}
+
+	cityCol := colOf["Customer / Address / City"]
+	if got := sheet.Rows[1].Cells[cityCol].Value; got != "London" {
+		t.Errorf("row 1 city = %q, want %q", got, "London")
+	}
+	if got := sheet.Rows[2].Cells[cityCol].Value; got != "" {
+		t.Errorf("nil Address should leave an empty cell, got %q", got)
+	}
+}
+
+type cyclicSelf struct {
+	Name string      `xls:"order=1,heading=Name"`
+	Self *cyclicSelf `xls:"order=2,heading=Self"`
+}
+
+// TestGetFieldSpecsDetectsDirectCycle covers a struct nesting a pointer
+// to its own type: without the ancestors guard, collectFieldSpecs would
+// recurse forever and stack-overflow instead of returning an error.
+func TestGetFieldSpecsDetectsDirectCycle(t *testing.T) {
+	if _, err := getFieldSpecs(reflect.TypeOf(cyclicSelf{})); err == nil {
+		t.Fatal("expected an error for a type that nests itself through a pointer")
+	}
+}
+
+type cyclicA struct {
+	B *cyclicB `xls:"order=1,heading=B"`
+}
+
+type cyclicB struct {
+	A *cyclicA `xls:"order=1,heading=A"`
+}
+
+// TestGetFieldSpecsDetectsIndirectCycle covers a cycle through two
+// distinct types (A -> B -> A) rather than a type nesting itself
+// directly.
+func TestGetFieldSpecsDetectsIndirectCycle(t *testing.T) {
+	if _, err := getFieldSpecs(reflect.TypeOf(cyclicA{})); err == nil {
+		t.Fatal("expected an error for a cycle spanning two struct types")
+	}
+}
@@ -0,0 +1,99 @@
+package xlsx_tags
+
+// Support for the formula= tag option, which lets a tagged field emit a
+// spreadsheet formula instead of a literal value. Templates may contain
+// ${col:Heading} placeholders, resolved to the A1-style column letter of
+// the column with that heading, and ${row}, resolved to the cell's own
+// row number.
+
+import (
+	"fmt"
+	"github.com/tealeg/xlsx"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var formulaPlaceholder = regexp.MustCompile(`\$\{col:([^}]+)\}|\$\{row\}`)
+
+// resolveFormula expands ${col:Heading} and ${row} placeholders in
+// template against headingToCol, a 1-based heading-to-column-index map,
+// and rowNum, the 1-based Excel row the formula is being written into.
+func resolveFormula(template string, headingToCol map[string]int, rowNum int) (string, error) {
+	var err error
+	resolved := formulaPlaceholder.ReplaceAllStringFunc(template, func(match string) string {
+		if match == "${row}" {
+			return strconv.Itoa(rowNum)
+		}
+		heading := match[len("${col:") : len(match)-1]
+		col, ok := headingToCol[heading]
+		if !ok {
+			err = fmt.Errorf("formula %q references unknown column %q", template, heading)
+			return match
+		}
+		return columnLetter(col)
+	})
+	if err != nil {
+		return "", err
+	}
+	return resolved, nil
+}
+
+// columnLetter converts a 1-based column index to its A1-style letter,
+// e.g. 1 -> "A", 27 -> "AA".
+func columnLetter(col int) string {
+	var letters string
+	for col > 0 {
+		col--
+		letters = string(rune('A'+col%26)) + letters
+		col /= 26
+	}
+	return letters
+}
+
+// WriteTotalsRow appends a totals/summary row to sheet, which must already
+// have a heading row written (e.g. via WriteToSheet). formulas maps a
+// column heading to a formula template: ${col} resolves to that column's
+// own letter, ${col:Heading} resolves to another column's letter, ${row}
+// resolves to the totals row's own number, and {lastRow} resolves to the
+// last existing data row number.
+func WriteTotalsRow(sheet *xlsx.Sheet, formulas map[string]string) error {
+	if len(sheet.Rows) == 0 {
+		return ErrNoHeadingRow
+	}
+
+	headingRow := sheet.Rows[0]
+	headingToCol := make(map[string]int, len(headingRow.Cells))
+	for i, cell := range headingRow.Cells {
+		headingToCol[cell.Value] = i + 1
+	}
+
+	lastRow := len(sheet.Rows)
+	totalsRowNum := lastRow + 1
+
+	row := sheet.AddRow()
+	cells := make([]*xlsx.Cell, len(headingRow.Cells))
+	for i := range cells {
+		cells[i] = row.AddCell()
+	}
+
+	for heading, template := range formulas {
+		col, ok := headingToCol[heading]
+		if !ok {
+			return fmt.Errorf("totals row references unknown column %q", heading)
+		}
+
+		expanded := strings.NewReplacer(
+			"${col}", columnLetter(col),
+			"{lastRow}", strconv.Itoa(lastRow),
+			"${lastRow}", strconv.Itoa(lastRow),
+		).Replace(template)
+
+		resolved, err := resolveFormula(expanded, headingToCol, totalsRowNum)
+		if err != nil {
+			return err
+		}
+		cells[col-1].SetFormula(resolved)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package xlsx_tags
+
+import (
+	"testing"
+
+	"github.com/tealeg/xlsx"
+)
+
+func TestResolveFormula(t *testing.T) {
+	headingToCol := map[string]int{"Qty": 1, "Price": 2}
+
+	got, err := resolveFormula("${col:Qty}${row}*${col:Price}${row}", headingToCol, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "A5*B5"; got != want {
+		t.Errorf("resolveFormula = %q, want %q", got, want)
+	}
+}
+
+func TestResolveFormulaUnknownColumn(t *testing.T) {
+	_, err := resolveFormula("${col:Missing}", map[string]int{"Qty": 1}, 2)
+	if err == nil {
+		t.Fatal("expected an error for an unresolvable column reference")
+	}
+}
+
+func TestColumnLetter(t *testing.T) {
+	cases := map[int]string{1: "A", 26: "Z", 27: "AA", 52: "AZ"}
+	for col, want := range cases {
+		if got := columnLetter(col); got != want {
+			t.Errorf("columnLetter(%d) = %q, want %q", col, got, want)
+		}
+	}
+}
+
+type totalsRow struct {
+	Qty   int `xls:"order=1,heading=Qty"`
+	Price int `xls:"order=2,heading=Price"`
+}
+
+func TestWriteTotalsRow(t *testing.T) {
+	rows := []totalsRow{{Qty: 1, Price: 10}, {Qty: 2, Price: 20}}
+
+	file := xlsx.NewFile()
+	sheet, err := file.AddSheet("S")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeWithTags(sheet, rows); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteTotalsRow(sheet, map[string]string{
+		"Qty": "SUM(${col}2:${col}{lastRow})",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	totals := sheet.Rows[len(sheet.Rows)-1]
+	if want := "SUM(A2:A3)"; totals.Cells[0].Formula() != want {
+		t.Errorf("totals Qty formula = %q, want %q", totals.Cells[0].Formula(), want)
+	}
+}
+
+func TestWriteTotalsRowUnknownHeading(t *testing.T) {
+	file := xlsx.NewFile()
+	sheet, err := file.AddSheet("S")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeWithTags(sheet, []totalsRow{{Qty: 1, Price: 10}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteTotalsRow(sheet, map[string]string{"Bogus": "SUM(${col}2:${col}{lastRow})"}); err == nil {
+		t.Fatal("expected an error for an unknown heading")
+	}
+}
+
+func TestWriteTotalsRowNoHeadingRow(t *testing.T) {
+	file := xlsx.NewFile()
+	sheet, err := file.AddSheet("S")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteTotalsRow(sheet, map[string]string{"Qty": "SUM(${col}2:${col}{lastRow})"}); err != ErrNoHeadingRow {
+		t.Fatalf("got %v, want ErrNoHeadingRow", err)
+	}
+}
@@ -0,0 +1,193 @@
+package xlsx_tags
+
+// provides helper utility for reading from the xls sheet into structs.
+// It is the read-side counterpart of WriteToSheet and understands the
+// same tag options: order, heading, format, walking nested/embedded
+// fields the same way writeWithTags's getFieldSpecs does, so a sheet
+// written from a flattened struct reads back into one.
+//
+// A zero time.Time field is written as zeroTimeSentinel and read back as
+// time.Time{} rather than parsed with its format layout. A formula= field
+// is written as a computed Excel formula and has no literal value to read
+// back, so round-tripping a struct through WriteToSheet and ReadFromSheet
+// is lossy for it: the field is left at its zero value on read.
+
+import (
+	"errors"
+	"fmt"
+	"github.com/tealeg/xlsx"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrUnsupportedTarget = errors.New("out must be a non-nil pointer to a slice of structs")
+	ErrNoHeadingRow      = errors.New("sheet has no heading row")
+)
+
+// RowError describes a failure while reading a specific sheet cell and
+// carries enough position info to find the offending cell in Excel.
+type RowError struct {
+	Row     int // 0-based data row index (heading row excluded)
+	Col     int // 0-based column index
+	Heading string
+	Err     error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d, col %d (%q): %v", e.Row, e.Col, e.Heading, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// ReadFromSheet populates out, a pointer to a slice of structs, from sheet.
+// The header row is matched against each field's tag, preferring a heading
+// match and falling back to order (1-based column position). The format
+// tag is used as a time.Parse layout for time.Time fields.
+func ReadFromSheet(sheet *xlsx.Sheet, out interface{}) error {
+	outVal := reflect.ValueOf(out)
+	if outVal.Kind() != reflect.Ptr || outVal.IsNil() || outVal.Elem().Kind() != reflect.Slice {
+		return ErrUnsupportedTarget
+	}
+
+	sliceVal := outVal.Elem()
+	itemType := sliceVal.Type().Elem()
+	if itemType.Kind() != reflect.Struct {
+		return ErrUnsupportedContentType
+	}
+
+	specs, err := getFieldSpecs(itemType)
+	if err != nil {
+		return err
+	}
+	opts := optsFromSpecs(specs)
+
+	if len(sheet.Rows) == 0 {
+		return ErrNoHeadingRow
+	}
+
+	colToOpt, err := mapColumnsToOpts(sheet.Rows[0], opts)
+	if err != nil {
+		return err
+	}
+
+	specByOrder := make(map[int]fieldSpec, len(specs))
+	for _, spec := range specs {
+		specByOrder[spec.opts.order] = spec
+	}
+
+	result := reflect.MakeSlice(sliceVal.Type(), 0, len(sheet.Rows)-1)
+	for rowIdx, row := range sheet.Rows[1:] {
+		item := reflect.New(itemType).Elem()
+		for col, opt := range colToOpt {
+			if opt == nil || col >= len(row.Cells) {
+				continue
+			}
+			spec, ok := specByOrder[opt.order]
+			if !ok {
+				continue
+			}
+			if opt.formula != "" {
+				// formula= fields hold a computed Excel formula, not a
+				// literal value; there is nothing to parse back, so the
+				// field is left at its zero value. See sheet_writer.go's
+				// formula handling in computeRowValues.
+				continue
+			}
+			if err := setFieldFromCell(fieldForSet(item, spec.path), row.Cells[col], opt.format); err != nil {
+				return &RowError{Row: rowIdx, Col: col, Heading: opt.heading, Err: err}
+			}
+		}
+		result = reflect.Append(result, item)
+	}
+
+	sliceVal.Set(result)
+	return nil
+}
+
+// mapColumnsToOpts maps each column index in the heading row to the
+// parseOpts it corresponds to, preferring a heading text match and
+// falling back to the column's 1-based position matching an order value.
+func mapColumnsToOpts(headingRow *xlsx.Row, opts []parseOpts) ([]*parseOpts, error) {
+	byHeading := make(map[string]*parseOpts, len(opts))
+	for i := range opts {
+		byHeading[opts[i].heading] = &opts[i]
+	}
+
+	colToOpt := make([]*parseOpts, len(headingRow.Cells))
+	for col, cell := range headingRow.Cells {
+		if opt, ok := byHeading[cell.Value]; ok {
+			colToOpt[col] = opt
+			continue
+		}
+		if opt := findOptByOrder(opts, col+1); opt != nil {
+			colToOpt[col] = opt
+		}
+	}
+	return colToOpt, nil
+}
+
+func findOptByOrder(opts []parseOpts, order int) *parseOpts {
+	for i := range opts {
+		if opts[i].order == order {
+			return &opts[i]
+		}
+	}
+	return nil
+}
+
+func setFieldFromCell(field reflect.Value, cell *xlsx.Cell, format string) error {
+	raw := cell.Value
+	switch field.Interface().(type) {
+	case time.Time:
+		if raw == zeroTimeSentinel {
+			field.Set(reflect.ValueOf(time.Time{}))
+			return nil
+		}
+		layout := format
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		t, err := time.Parse(layout, raw)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+	case int, int8, int16, int32, int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case float32, float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case uint, uint8, uint16, uint32, uint64:
+		n, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case string:
+		field.SetString(raw)
+	default:
+		if field.Kind() == reflect.String {
+			field.SetString(raw)
+			return nil
+		}
+		return fmt.Errorf("xlsx_tags: cannot set %s field from cell value %q: unsupported kind", field.Kind(), raw)
+	}
+	return nil
+}
@@ -4,15 +4,17 @@ package xlsx_tags
 // Available tag options:
 // 	order - positive number, defines cells ordering, required,
 // 	heading - column title, required
-// 	format - optional formatting
+// 	format - optional formatting: a time.Parse/Format layout for time.Time
+// 	         fields, or (for backward compatibility) an Excel number format
+// 	         for numeric fields when numFmt is not set
+// 	numFmt - optional Excel number format applied to the cell (e.g.
+// 	         "#,##0.00", "yyyy-mm-dd")
 
 import (
 	"errors"
 	"fmt"
 	"github.com/tealeg/xlsx"
 	"reflect"
-	"regexp"
-	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -20,23 +22,78 @@ import (
 
 const tagName = "xls"
 
+// zeroTimeSentinel is written in place of a zero-valued time.Time field,
+// which has no sensible time.Parse layout of its own. ReadFromSheet
+// recognises it and reads the field back as time.Time{} instead of
+// failing to parse it.
+const zeroTimeSentinel = " - "
+
 var (
 	ErrUnsupportedType        = errors.New("trying to marshal unsupported type. Supported types are: array, slice, struct")
 	ErrUnsupportedContentType = errors.New("trying to marshal unsupported content type. Currently supports only struct")
 	ErrHeadingPropRequired    = errors.New("heading property must be set")
 )
 
-var marshallerType = reflect.TypeOf((Marshaller)(nil))
+// Marshaller lets a type take over its own sheet encoding instead of going
+// through the tag-driven reflection path.
+type Marshaller interface {
+	Header() []string
+	Data() [][]string
+}
+
+var marshallerType = reflect.TypeOf((*Marshaller)(nil)).Elem()
 
 // todo think about name correctness
 type parseOpts struct {
 	order   int
 	heading string
 	format  string
+	// numFmt is an Excel number format string (e.g. "#,##0.00", "yyyy-mm-dd")
+	// applied to the cell via cell.NumFmt. When unset, a non-Go-layout
+	// format tag on a numeric field is used as the numFmt instead, so
+	// existing tags keep working.
+	numFmt string
+	// formula, when set, is written via cell.SetFormula instead of the
+	// field's own value, after resolving ${col:Heading} and ${row}
+	// placeholders.
+	formula string
+	// Styling options, only honoured by WriteToSheetWithOptions.
+	width int    // explicit column width; 0 means auto-size when AutoWidth is set
+	align string // "left", "right" or "center"
+	color string // hex font color, e.g. "#FF0000"
+	wrap  bool
+	cond  string // conditional-format DSL, e.g. ">100:red;<0:yellow"
 }
 
+// hasFormatting reports whether a format tag was set. It used to return
+// the inverse of this, which silently broke every caller that branched
+// on it.
 func (o parseOpts) hasFormatting() bool {
-	return o.format == ""
+	return o.format != ""
+}
+
+// effectiveNumFmt resolves the Excel number format to apply to a cell for
+// this option. numFmt always wins; otherwise, for non-time fields, a
+// format tag that isn't a Go reference layout is treated as a numFmt so
+// that tags written before numFmt existed keep working unchanged.
+func effectiveNumFmt(o parseOpts, isTime bool) string {
+	if o.numFmt != "" {
+		return o.numFmt
+	}
+	if !isTime && o.hasFormatting() && !isGoReferenceLayout(o.format) {
+		return o.format
+	}
+	return ""
+}
+
+// isGoReferenceLayout reports whether format looks like a Go reference
+// time layout (built from the "Mon Jan 2 15:04:05 2006" reference date)
+// rather than an Excel number format pattern.
+func isGoReferenceLayout(format string) bool {
+	return strings.Contains(format, "2006") ||
+		strings.Contains(format, "Jan") ||
+		strings.Contains(format, "Mon") ||
+		strings.Contains(format, "15:04")
 }
 
 type optsByOrder []parseOpts
@@ -77,63 +134,69 @@ func writeWithMarshaller(sheet *xlsx.Sheet, data interface{}) error {
 func writeWithTags(sheet *xlsx.Sheet, data interface{}) error {
 	v := reflect.ValueOf(data)
 	itemsType := getListType(data)
-	opts, err := getParseOptions(itemsType)
+	specs, err := getFieldSpecs(itemsType)
 	if err != nil {
 		return err
 	}
-	sort.Sort(optsByOrder(opts))
-	// stores mapping between order and cell position
-	orderToCellPos := make(map[int]int)
-	for i, opt := range opts {
-		orderToCellPos[opt.order] = i
-	}
+	opts := optsFromSpecs(specs)
+
 	// write heading
 	writeHeadingFromOpts(sheet, opts)
 
-	values := make([]string, len(opts))
+	headingToCol := make(map[string]int, len(opts))
+	for i, opt := range opts {
+		headingToCol[opt.heading] = i + 1
+	}
+
 	for i := 0; i < v.Len(); i++ {
-		item := v.Index(i)
-		values = values[:]
-		for j := 0; j < item.NumField(); j++ {
-			tag := item.Type().Field(j).Tag.Get(tagName)
-
-			// Skip if tag is not defined or ignored
-			if tag == "" || tag == "-" {
-				continue
+		values, err := computeRowValues(v.Index(i), specs, headingToCol, i+2)
+		if err != nil {
+			return err
+		}
+		writeTypedRow(sheet, values)
+	}
+	return nil
+}
+
+// computeRowValues builds the cellValues for one data row by following
+// each spec's field-index path into item (descending through embedded
+// and pointer-to-struct fields as needed), then overlays any formula=
+// columns resolved against rowNum, the 1-based Excel row this data is
+// being written into. A nil pointer along a path leaves that cell empty.
+func computeRowValues(item reflect.Value, specs []fieldSpec, headingToCol map[string]int, rowNum int) ([]cellValue, error) {
+	values := make([]cellValue, len(specs))
+	for pos, spec := range specs {
+		opt := spec.opts
+		if opt.formula != "" {
+			resolved, err := resolveFormula(opt.formula, headingToCol, rowNum)
+			if err != nil {
+				return nil, err
 			}
+			values[pos] = cellValue{formula: resolved}
+			continue
+		}
 
-			// it will not trigger an error as we already verified it
-			order, _ := orderFromTag(tag)
-			pos := orderToCellPos[order]
-			opt := opts[pos]
-			switch val := item.Field(j).Interface().(type) {
-			// todo maybe pass it to the default case
-			case int, int8, int16, int32, int64, float32, float64, string:
-				format := "%v"
-				if opt.hasFormatting() {
-					format = opt.format
-				}
-				values[pos] = fmt.Sprintf(format, val)
-			case time.Time:
-				var s string
-				if opt.hasFormatting() {
-					s = val.Format(opt.format)
-				} else {
-					s = val.String()
-				}
-				if val.IsZero() {
-					s = " - "
-				}
-				values[pos] = s
-			case fmt.Stringer:
-				values[pos] = val.String()
-			default:
-				values[pos] = fmt.Sprintf("%v", val)
+		fieldVal, ok := fieldByPath(item, spec.path)
+		if !ok {
+			continue
+		}
+
+		switch val := fieldVal.Interface().(type) {
+		case time.Time:
+			if val.IsZero() {
+				values[pos] = cellValue{value: zeroTimeSentinel}
+			} else if opt.hasFormatting() && isGoReferenceLayout(opt.format) {
+				values[pos] = cellValue{value: val.Format(opt.format)}
+			} else {
+				values[pos] = cellValue{value: val, numFmt: effectiveNumFmt(opt, true)}
 			}
+		case fmt.Stringer:
+			values[pos] = cellValue{value: val.String()}
+		default:
+			values[pos] = cellValue{value: val, numFmt: effectiveNumFmt(opt, false)}
 		}
-		writeRow(sheet, values)
 	}
-	return nil
+	return values, nil
 }
 
 func getParseOptions(data reflect.Type) ([]parseOpts, error) {
@@ -176,65 +239,108 @@ func writeRow(sheet *xlsx.Sheet, data []string) {
 	}
 }
 
-// todo modify options parsing
-var (
-	orderRegex   = regexp.MustCompile(`order=(?P<order>[\d\s\w]+),?|$`)
-	headingRegex = regexp.MustCompile(`heading=(?P<heading>("[#\w.,-/\\ ]+")|([#\w\s]+))(,|$)`)
-	formatRegex  = regexp.MustCompile(`format=(?P<format>[-\w\s%.\d\\/]+)(,|$)`)
-)
+// cellValue is a single tagged-field value together with the Excel number
+// format, if any, that should be applied to the cell it is written into.
+// When formula is set it is written via cell.SetFormula instead of value.
+type cellValue struct {
+	value   interface{}
+	numFmt  string
+	formula string
+}
 
+func writeTypedRow(sheet *xlsx.Sheet, data []cellValue) {
+	row := sheet.AddRow()
+	for _, cv := range data {
+		cell := row.AddCell()
+		if cv.formula != "" {
+			cell.SetFormula(cv.formula)
+		} else {
+			setCellValue(cell, cv.value)
+		}
+		if cv.numFmt != "" {
+			cell.NumFmt = cv.numFmt
+		}
+	}
+}
+
+// setCellValue assigns val to cell using tealeg/xlsx's typed setters so
+// Excel treats the value as a number, date or boolean instead of text.
+// fmt.Stringer and any other unrecognised type fall back to SetString.
+func setCellValue(cell *xlsx.Cell, val interface{}) {
+	if val == nil {
+		return
+	}
+	switch v := val.(type) {
+	case int:
+		cell.SetInt64(int64(v))
+	case int8:
+		cell.SetInt64(int64(v))
+	case int16:
+		cell.SetInt64(int64(v))
+	case int32:
+		cell.SetInt64(int64(v))
+	case int64:
+		cell.SetInt64(v)
+	case float32:
+		cell.SetFloat(float64(v))
+	case float64:
+		cell.SetFloat(v)
+	case bool:
+		cell.SetBool(v)
+	case string:
+		cell.SetString(v)
+	case time.Time:
+		cell.SetDateTime(v)
+	case fmt.Stringer:
+		cell.SetString(v.String())
+	default:
+		cell.SetString(fmt.Sprintf("%v", v))
+	}
+}
+
+// parseOptFromTag parses a raw `xls:"..."` tag value into a parseOpts.
+// See tag_parser.go for the tokenizer this is built on.
 func parseOptFromTag(tag string) (parseOpts, error) {
-	order, err := orderFromTag(tag)
+	values, err := parseTag(tag)
 	if err != nil {
 		return parseOpts{}, err
 	}
-	heading, err := headingFromTag(tag)
+
+	order, err := strconv.Atoi(strings.TrimSpace(values["order"]))
 	if err != nil {
-		return parseOpts{}, err
+		return parseOpts{}, fmt.Errorf("xls tag %q: invalid order: %w", tag, err)
 	}
+
+	heading := strings.TrimSpace(values["heading"])
+	if heading == "" {
+		return parseOpts{}, ErrHeadingPropRequired
+	}
+
+	width, _ := strconv.Atoi(strings.TrimSpace(values["width"]))
+	_, wrap := values["wrap"]
+
 	return parseOpts{
 		order:   order,
 		heading: heading,
-		format:  formatFromTag(tag),
+		format:  values["format"],
+		numFmt:  values["numFmt"],
+		formula: values["formula"],
+		width:   width,
+		align:   values["align"],
+		color:   values["color"],
+		wrap:    wrap,
+		cond:    values["cond"],
 	}, nil
 }
 
+// orderFromTag is a convenience wrapper around parseTag for call sites
+// that only need the order of an already-validated tag.
 func orderFromTag(tag string) (int, error) {
-	submatches := findStringSubmatchMap(orderRegex, tag)
-	return strconv.Atoi(submatches["order"])
-}
-
-func headingFromTag(tag string) (string, error) {
-	submatches := findStringSubmatchMap(headingRegex, tag)
-	heading := strings.Trim(submatches["heading"], "\"")
-	heading = strings.TrimSpace(heading)
-	if heading != "" {
-		return heading, nil
-	}
-	return heading, ErrHeadingPropRequired
-}
-
-func formatFromTag(tag string) string {
-	submatches := findStringSubmatchMap(formatRegex, tag)
-	return submatches["format"]
-}
-
-func findStringSubmatchMap(r *regexp.Regexp, s string) map[string]string {
-	captures := make(map[string]string)
-
-	match := r.FindStringSubmatch(s)
-	if match == nil {
-		return captures
-	}
-
-	for i, name := range r.SubexpNames() {
-		// Ignore the whole regexp match and unnamed groups
-		if i == 0 || name == "" {
-			continue
-		}
-		captures[name] = match[i]
+	values, err := parseTag(tag)
+	if err != nil {
+		return 0, err
 	}
-	return captures
+	return strconv.Atoi(strings.TrimSpace(values["order"]))
 }
 
 func getListType(s interface{}) reflect.Type {
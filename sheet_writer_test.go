@@ -0,0 +1,90 @@
+package xlsx_tags
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tealeg/xlsx"
+)
+
+type writerTestRow struct {
+	ID      int64     `xls:"order=1,heading=ID"`
+	Qty     int       `xls:"order=2,heading=Qty"`
+	Price   float64   `xls:"order=3,heading=Price,numFmt=\"#,##0.00\""`
+	Active  bool      `xls:"order=4,heading=Active"`
+	Created time.Time `xls:"order=5,heading=Created,format=2006-01-02"`
+}
+
+// TestWriteToSheetTypedCells exercises the exported WriteToSheet entry
+// point directly, rather than writeWithTags, so a regression in the
+// Implements(marshallerType) dispatch at the top of WriteToSheet is
+// caught instead of silently routed around.
+func TestWriteToSheetTypedCells(t *testing.T) {
+	rows := []writerTestRow{
+		{ID: 1, Qty: 3, Price: 9.5, Active: true, Created: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}
+
+	file := xlsx.NewFile()
+	sheet, err := file.AddSheet("S")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteToSheet(sheet, rows); err != nil {
+		t.Fatal(err)
+	}
+
+	cells := sheet.Rows[1].Cells
+	if got, _ := cells[0].Int64(); got != 1 {
+		t.Errorf("ID cell = %v, want 1 as a typed int", got)
+	}
+	if got, _ := cells[1].Int64(); got != 3 {
+		t.Errorf("Qty cell = %v, want 3 as a typed int", got)
+	}
+	if got, _ := cells[2].Float(); got != 9.5 {
+		t.Errorf("Price cell = %v, want 9.5 as a typed float", got)
+	}
+	if want := "#,##0.00"; cells[2].NumFmt != want {
+		t.Errorf("Price numFmt = %q, want %q", cells[2].NumFmt, want)
+	}
+	if got := cells[3].Bool(); !got {
+		t.Errorf("Active cell = %v, want true as a typed bool", got)
+	}
+	if got := cells[4].Value; got != "2024-01-02" {
+		t.Errorf("Created cell = %q, want %q", got, "2024-01-02")
+	}
+}
+
+// TestWriteToSheetMarshaller exercises the Marshaller dispatch branch of
+// WriteToSheet; without a correctly constructed marshallerType this
+// panics on the Implements check before reaching either branch.
+func TestWriteToSheetMarshaller(t *testing.T) {
+	file := xlsx.NewFile()
+	sheet, err := file.AddSheet("S")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteToSheet(sheet, marshallerRows{{a: "x", b: "1"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := sheet.Rows[0].Cells[0].Value; got != "A" {
+		t.Errorf("heading cell = %q, want %q", got, "A")
+	}
+	if got := sheet.Rows[1].Cells[0].Value; got != "x" {
+		t.Errorf("data cell = %q, want %q", got, "x")
+	}
+}
+
+// marshallerRows implements Marshaller directly on the slice type, the
+// shape WriteToSheet's v.Type().Implements(marshallerType) check expects
+// data to satisfy.
+type marshallerRows []struct{ a, b string }
+
+func (marshallerRows) Header() []string { return []string{"A", "B"} }
+
+func (r marshallerRows) Data() [][]string {
+	out := make([][]string, len(r))
+	for i, row := range r {
+		out[i] = []string{row.a, row.b}
+	}
+	return out
+}
@@ -0,0 +1,178 @@
+package xlsx_tags
+
+// Streaming counterpart to writeWithTags for large datasets: the tagged
+// reflection plan is computed once per call instead of being re-derived
+// for every row, and rows are flushed to the underlying zip as they
+// arrive instead of being held in an in-memory *xlsx.Sheet. It is backed
+// by tealeg/xlsx's StreamFileBuilder/StreamFile, which is why it writes
+// to an io.Writer rather than an existing *xlsx.File: StreamFileBuilder
+// owns the zip output itself and isn't a sheet that can be added to an
+// already-open *xlsx.File.
+
+import (
+	"fmt"
+	"github.com/tealeg/xlsx"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// fieldPlan binds a struct field to its resolved column position and
+// parse options, so a row can be written without re-reading struct tags.
+type fieldPlan struct {
+	fieldIndex int
+	pos        int
+	opt        parseOpts
+}
+
+// buildFieldPlan walks itemType's tags once, sorting columns by order,
+// and returns the reflection plan together with the sorted opts it was
+// derived from. Only top-level tagged fields are considered; it does not
+// understand the nested/embedded flattening that writeWithTags supports.
+func buildFieldPlan(itemType reflect.Type) ([]fieldPlan, []parseOpts, error) {
+	opts, err := getParseOptions(itemType)
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Sort(optsByOrder(opts))
+
+	orderToPos := make(map[int]int, len(opts))
+	for i, opt := range opts {
+		orderToPos[opt.order] = i
+	}
+
+	plan := make([]fieldPlan, 0, len(opts))
+	for i := 0; i < itemType.NumField(); i++ {
+		tag := itemType.Field(i).Tag.Get(tagName)
+		if tag == "" || tag == "-" {
+			continue
+		}
+		order, _ := orderFromTag(tag)
+		pos := orderToPos[order]
+		plan = append(plan, fieldPlan{fieldIndex: i, pos: pos, opt: opts[pos]})
+	}
+	return plan, opts, nil
+}
+
+// streamCellMetadataFor picks the default xlsx.CellMetadata a streamed
+// column should declare, based on the Go kind of the field that feeds
+// it. tealeg/xlsx v1.0.5's CellType.fallbackTo only special-cases
+// CellTypeNumeric when deciding whether a written string round-trips as
+// its declared type, so only int/float columns come back as real
+// numeric Excel cells; everything else, including time.Time, is given
+// string metadata and written as formatted text.
+func streamCellMetadataFor(fieldType reflect.Type) *xlsx.CellMetadata {
+	switch fieldType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return xlsx.DefaultIntegerCellMetadata.Ptr()
+	case reflect.Float32, reflect.Float64:
+		return xlsx.DefaultDecimalCellMetadata.Ptr()
+	default:
+		return xlsx.DefaultStringCellMetadata.Ptr()
+	}
+}
+
+// WriteToStreamingSheet writes sheetName to w, one row per item drained
+// from ch, via tealeg/xlsx's StreamFileBuilder/StreamFile: every row is
+// marshalled and flushed straight to the output zip as it's written,
+// rather than appended to an in-memory *xlsx.Sheet the way WriteToSheet
+// does, so writing hundreds of thousands of rows doesn't hold the whole
+// dataset in memory. The reflection plan for the item type
+// (buildFieldPlan) is built once, from the first item received, and
+// reused for every subsequent row. ch should be closed by the producer
+// once all items have been sent; a bounded or unbuffered channel
+// naturally back-pressures the producer to the speed rows are flushed
+// at.
+//
+// The sheet, including its header row, is only created once the first
+// item arrives, because tealeg/xlsx needs each column's CellMetadata
+// declared up front; a ch that closes without ever sending an item
+// produces no output at all.
+func WriteToStreamingSheet(w io.Writer, sheetName string, headers []string, ch <-chan interface{}) error {
+	builder := xlsx.NewStreamFileBuilder(w)
+
+	var (
+		plan     []fieldPlan
+		planType reflect.Type
+		sf       *xlsx.StreamFile
+	)
+	for item := range ch {
+		v := reflect.ValueOf(item)
+		if v.Kind() != reflect.Struct {
+			return ErrUnsupportedContentType
+		}
+		if planType != nil && v.Type() != planType {
+			return fmt.Errorf("xlsx_tags: WriteToStreamingSheet: item type %s does not match the first item's type %s", v.Type(), planType)
+		}
+
+		if plan == nil {
+			var err error
+			plan, _, err = buildFieldPlan(v.Type())
+			if err != nil {
+				return err
+			}
+			planType = v.Type()
+
+			metas := make([]*xlsx.CellMetadata, len(headers))
+			for _, p := range plan {
+				if p.pos < len(metas) {
+					metas[p.pos] = streamCellMetadataFor(v.Type().Field(p.fieldIndex).Type)
+				}
+			}
+			if err := builder.AddSheetWithDefaultColumnMetadata(sheetName, headers, metas); err != nil {
+				return err
+			}
+			if sf, err = builder.Build(); err != nil {
+				return err
+			}
+		}
+
+		row := make([]string, len(headers))
+		for _, p := range plan {
+			if p.pos >= len(row) {
+				continue
+			}
+			row[p.pos] = formatPlanValue(p.opt, v.Field(p.fieldIndex).Interface())
+		}
+		if err := sf.WriteWithColumnDefaultMetadata(row); err != nil {
+			return err
+		}
+	}
+	if sf == nil {
+		return nil
+	}
+	return sf.Close()
+}
+
+// formatPlanValue mirrors the per-field conversion writeWithTags does,
+// but against an already-resolved fieldPlan entry instead of a freshly
+// parsed tag, and renders to a string: StreamFile.WriteWithColumnDefaultMetadata
+// only accepts row cells as strings, letting the column's CellMetadata
+// decide how each one is reinterpreted when marshalled. Unlike
+// writeWithTags, it does not apply formula= or numFmt=: a formula= field
+// is written as its literal value.
+func formatPlanValue(opt parseOpts, val interface{}) string {
+	switch v := val.(type) {
+	case time.Time:
+		if v.IsZero() {
+			return zeroTimeSentinel
+		}
+		if opt.hasFormatting() && isGoReferenceLayout(opt.format) {
+			return v.Format(opt.format)
+		}
+		return v.Format("2006-01-02")
+	case fmt.Stringer:
+		return v.String()
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,28 @@
+package xlsx_tags
+
+import (
+	"io"
+	"testing"
+)
+
+type streamBenchRow struct {
+	ID    int64   `xls:"order=1,heading=ID"`
+	Name  string  `xls:"order=2,heading=Name"`
+	Price float64 `xls:"order=3,heading=Price"`
+}
+
+func BenchmarkWriteToStreamingSheet(b *testing.B) {
+	headers := []string{"ID", "Name", "Price"}
+	for i := 0; i < b.N; i++ {
+		ch := make(chan interface{}, 64)
+		go func() {
+			defer close(ch)
+			for j := 0; j < 10000; j++ {
+				ch <- streamBenchRow{ID: int64(j), Name: "item", Price: float64(j) * 1.5}
+			}
+		}()
+		if err := WriteToStreamingSheet(io.Discard, "Sheet1", headers, ch); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package xlsx_tags
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tealeg/xlsx"
+)
+
+type mixedA struct {
+	ID   int64  `xls:"order=1,heading=ID"`
+	Name string `xls:"order=2,heading=Name"`
+}
+
+type mixedB struct {
+	OnlyOne int64 `xls:"order=1,heading=ID"`
+}
+
+// TestWriteToStreamingSheetRejectsMixedTypes covers the fieldPlan derived
+// from the first item no longer being blindly reused against a later
+// item of a different concrete type: ch's element type is interface{},
+// so nothing at compile time guarantees every item shares the same
+// struct type the way WriteToSheet's slice parameter does.
+func TestWriteToStreamingSheetRejectsMixedTypes(t *testing.T) {
+	ch := make(chan interface{}, 2)
+	ch <- mixedA{ID: 1, Name: "a"}
+	ch <- mixedB{OnlyOne: 2}
+	close(ch)
+
+	var buf bytes.Buffer
+	err := WriteToStreamingSheet(&buf, "Sheet1", []string{"ID", "Name"}, ch)
+	if err == nil {
+		t.Fatal("expected an error for a channel yielding mixed item types")
+	}
+}
+
+func TestWriteToStreamingSheetRoundTrip(t *testing.T) {
+	headers := []string{"ID", "Name", "Price"}
+	ch := make(chan interface{}, 2)
+	ch <- streamBenchRow{ID: 1, Name: "a", Price: 1.5}
+	ch <- streamBenchRow{ID: 2, Name: "b", Price: 2.25}
+	close(ch)
+
+	var buf bytes.Buffer
+	if err := WriteToStreamingSheet(&buf, "Sheet1", headers, ch); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := xlsx.OpenBinary(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet := file.Sheets[0]
+	if len(sheet.Rows) != 3 {
+		t.Fatalf("got %d rows, want 3 (1 header + 2 data)", len(sheet.Rows))
+	}
+	if got := sheet.Rows[1].Cells[1].Value; got != "a" {
+		t.Errorf("row 1 Name = %q, want %q", got, "a")
+	}
+}
@@ -0,0 +1,352 @@
+package xlsx_tags
+
+// Styling subsystem for WriteToSheetWithOptions: bold/colored headers,
+// column widths, frozen header row, autofilter and zebra-striped data
+// rows, plus the per-field width=, align=, color=, wrap and cond= tag
+// options. Styles are deduplicated through a per-call styleKey cache so
+// a large sheet doesn't allocate a style per cell.
+
+import (
+	"fmt"
+	"github.com/tealeg/xlsx"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WriteOptions controls the presentational aspects of
+// WriteToSheetWithOptions.
+type WriteOptions struct {
+	BoldHeader   bool
+	HeaderColor  string // hex font color for the header row, e.g. "#FFFFFF"
+	HeaderFill   string // hex background color for the header row
+	AutoWidth    bool   // auto-size columns to the longest value written, unless a width= tag overrides it
+	FreezeHeader bool
+	AutoFilter   bool
+	ZebraStripe  bool
+	ZebraColor   string // background color for odd data rows; defaults to "#F2F2F2"
+}
+
+const defaultZebraColor = "#F2F2F2"
+
+// WriteToSheetWithOptions is the styled counterpart of WriteToSheet: it
+// writes the same order=/heading=/format=/numFmt=/formula= tagged data,
+// plus width=, align=, color=, wrap and cond= styling, and applies opts
+// to the header row, column widths and overall sheet presentation.
+func WriteToSheetWithOptions(sheet *xlsx.Sheet, data interface{}, opts WriteOptions) error {
+	v := reflect.ValueOf(data)
+	kind := v.Kind()
+	if kind != reflect.Array && kind != reflect.Slice {
+		return ErrUnsupportedType
+	}
+
+	itemsType := getListType(data)
+	if itemsType.Kind() != reflect.Struct {
+		return ErrUnsupportedContentType
+	}
+
+	specs, err := getFieldSpecs(itemsType)
+	if err != nil {
+		return err
+	}
+	fieldOpts := optsFromSpecs(specs)
+
+	headingToCol := make(map[string]int, len(fieldOpts))
+	for i, opt := range fieldOpts {
+		headingToCol[opt.heading] = i + 1
+	}
+
+	condRules := make([][]condRule, len(fieldOpts))
+	for i, opt := range fieldOpts {
+		if opt.cond == "" {
+			continue
+		}
+		rules, err := parseCond(opt.cond)
+		if err != nil {
+			return fmt.Errorf("xlsx_tags: heading %q: %w", opt.heading, err)
+		}
+		condRules[i] = rules
+	}
+
+	styles := make(map[styleKey]*xlsx.Style)
+
+	writeHeadingFromOpts(sheet, fieldOpts)
+	if opts.BoldHeader || opts.HeaderColor != "" || opts.HeaderFill != "" {
+		headerStyle := styleFor(styles, styleKey{bold: opts.BoldHeader, color: opts.HeaderColor, fill: opts.HeaderFill})
+		for _, cell := range sheet.Rows[len(sheet.Rows)-1].Cells {
+			cell.SetStyle(headerStyle)
+		}
+	}
+
+	longest := make([]int, len(fieldOpts))
+	for i, opt := range fieldOpts {
+		longest[i] = len(opt.heading)
+	}
+
+	for i := 0; i < v.Len(); i++ {
+		values, err := computeRowValues(v.Index(i), specs, headingToCol, i+2)
+		if err != nil {
+			return err
+		}
+		writeTypedRow(sheet, values)
+		row := sheet.Rows[len(sheet.Rows)-1]
+
+		for pos, opt := range fieldOpts {
+			cell := row.Cells[pos]
+
+			var key styleKey
+			styled := false
+			if opt.align != "" {
+				key.align = opt.align
+				styled = true
+			}
+			if opt.wrap {
+				key.wrap = true
+				styled = true
+			}
+			if opt.color != "" {
+				key.color = opt.color
+				styled = true
+			}
+			if opts.ZebraStripe && i%2 == 1 {
+				key.fill = zebraColor(opts)
+				styled = true
+			}
+			if rules := condRules[pos]; rules != nil {
+				if color, ok := evalCond(rules, values[pos].value); ok {
+					key.fill = color
+					styled = true
+				}
+			}
+			if styled {
+				cell.SetStyle(styleFor(styles, key))
+			}
+
+			if opts.AutoWidth {
+				if l := cellDisplayLen(values[pos].value); l > longest[pos] {
+					longest[pos] = l
+				}
+			}
+		}
+	}
+
+	for i, opt := range fieldOpts {
+		width := float64(opt.width)
+		if width == 0 && opts.AutoWidth {
+			width = float64(longest[i]) + 2
+		}
+		if width > 0 {
+			if err := sheet.SetColWidth(i, i, width); err != nil {
+				return err
+			}
+		}
+	}
+
+	if opts.FreezeHeader {
+		freezeHeaderRow(sheet)
+	}
+	if opts.AutoFilter && len(fieldOpts) > 0 {
+		sheet.AutoFilter = &xlsx.AutoFilter{
+			TopLeftCell:     "A1",
+			BottomRightCell: fmt.Sprintf("%s%d", columnLetter(len(fieldOpts)), len(sheet.Rows)),
+		}
+	}
+
+	return nil
+}
+
+// freezeHeaderRow pins sheet's first row in place by declaring a frozen
+// pane below it. *xlsx.Sheet has no SetFrozen helper in tealeg/xlsx
+// v1.0.5; a frozen pane is expressed directly via SheetViews, mirroring
+// what makeXLSXSheet emits for the <pane> element.
+func freezeHeaderRow(sheet *xlsx.Sheet) {
+	sheet.SheetViews = []xlsx.SheetView{{
+		Pane: &xlsx.Pane{
+			YSplit:      1,
+			TopLeftCell: "A2",
+			ActivePane:  "bottomLeft",
+			State:       "frozen",
+		},
+	}}
+}
+
+func zebraColor(opts WriteOptions) string {
+	if opts.ZebraColor != "" {
+		return opts.ZebraColor
+	}
+	return defaultZebraColor
+}
+
+func cellDisplayLen(val interface{}) int {
+	switch v := val.(type) {
+	case string:
+		return len(v)
+	case time.Time:
+		return len(v.Format("2006-01-02"))
+	default:
+		return len(fmt.Sprintf("%v", v))
+	}
+}
+
+// styleKey identifies a distinct combination of styling attributes, used
+// to deduplicate *xlsx.Style instances across an entire sheet.
+type styleKey struct {
+	bold  bool
+	color string
+	fill  string
+	align string
+	wrap  bool
+}
+
+func styleFor(cache map[styleKey]*xlsx.Style, key styleKey) *xlsx.Style {
+	if style, ok := cache[key]; ok {
+		return style
+	}
+
+	style := xlsx.NewStyle()
+	if key.bold || key.color != "" {
+		style.Font.Bold = key.bold
+		if key.color != "" {
+			style.Font.Color = strings.TrimPrefix(key.color, "#")
+		}
+		style.ApplyFont = true
+	}
+	if key.fill != "" {
+		style.Fill.PatternType = "solid"
+		style.Fill.FgColor = strings.TrimPrefix(key.fill, "#")
+		style.ApplyFill = true
+	}
+	if key.align != "" || key.wrap {
+		style.Alignment.Horizontal = key.align
+		style.Alignment.WrapText = key.wrap
+		style.ApplyAlignment = true
+	}
+
+	cache[key] = style
+	return style
+}
+
+// Mini conditional-format DSL: semicolon-separated rules of the form
+// "<op><threshold>:<color>", e.g. ">100:red;<0:yellow". The first rule
+// whose comparison holds against the cell's numeric value wins, and its
+// color is baked in as a static fill on that cell's style at write time.
+//
+// This is NOT a real Excel conditional-formatting rule: tealeg/xlsx
+// v1.0.5 has no support for writing the <conditionalFormatting> part, so
+// there is nothing to compile the DSL into. The fill does not re-evaluate
+// if the value is edited in Excel afterwards. Each column's cond tag is
+// parsed once, by WriteToSheetWithOptions before the row loop, and the
+// resulting []condRule is reused for every row.
+
+type condRule struct {
+	op    string
+	value float64
+	color string
+}
+
+var condOps = []string{">=", "<=", "==", "!=", ">", "<"}
+
+func parseCond(cond string) ([]condRule, error) {
+	var rules []condRule
+	for _, part := range strings.Split(cond, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		kv := strings.SplitN(part, ":", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid cond rule %q", part)
+		}
+		expr, color := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+
+		op, thresholdStr := splitCondOp(expr)
+		if op == "" {
+			return nil, fmt.Errorf("invalid cond expression %q", expr)
+		}
+		threshold, err := strconv.ParseFloat(strings.TrimSpace(thresholdStr), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cond threshold in %q: %w", expr, err)
+		}
+
+		rules = append(rules, condRule{op: op, value: threshold, color: colorToHex(color)})
+	}
+	return rules, nil
+}
+
+func splitCondOp(expr string) (op, rest string) {
+	for _, candidate := range condOps {
+		if strings.HasPrefix(expr, candidate) {
+			return candidate, expr[len(candidate):]
+		}
+	}
+	return "", ""
+}
+
+func (r condRule) matches(value float64) bool {
+	switch r.op {
+	case ">":
+		return value > r.value
+	case "<":
+		return value < r.value
+	case ">=":
+		return value >= r.value
+	case "<=":
+		return value <= r.value
+	case "==":
+		return value == r.value
+	case "!=":
+		return value != r.value
+	}
+	return false
+}
+
+var namedCondColors = map[string]string{
+	"red":    "FF0000",
+	"yellow": "FFFF00",
+	"green":  "00FF00",
+}
+
+func colorToHex(color string) string {
+	if hex, ok := namedCondColors[strings.ToLower(color)]; ok {
+		return hex
+	}
+	return strings.TrimPrefix(color, "#")
+}
+
+// evalCond returns the fill color of the first rule in rules that matches
+// value, if value is numeric and any rule matches.
+func evalCond(rules []condRule, value interface{}) (string, bool) {
+	num, ok := toFloat(value)
+	if !ok {
+		return "", false
+	}
+	for _, rule := range rules {
+		if rule.matches(num) {
+			return rule.color, true
+		}
+	}
+	return "", false
+}
+
+func toFloat(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
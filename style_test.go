@@ -0,0 +1,92 @@
+package xlsx_tags
+
+import (
+	"testing"
+
+	"github.com/tealeg/xlsx"
+)
+
+func TestParseCond(t *testing.T) {
+	rules, err := parseCond(">100:red;<0:yellow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("got %d rules, want 2", len(rules))
+	}
+	if rules[0].op != ">" || rules[0].value != 100 || rules[0].color != "FF0000" {
+		t.Errorf("rule 0 = %+v", rules[0])
+	}
+	if rules[1].op != "<" || rules[1].value != 0 || rules[1].color != "FFFF00" {
+		t.Errorf("rule 1 = %+v", rules[1])
+	}
+}
+
+func TestParseCondInvalid(t *testing.T) {
+	if _, err := parseCond("notarule"); err == nil {
+		t.Fatal("expected an error for a malformed cond rule")
+	}
+	if _, err := parseCond(">abc:red"); err == nil {
+		t.Fatal("expected an error for a non-numeric threshold")
+	}
+}
+
+func TestEvalCond(t *testing.T) {
+	rules, err := parseCond(">100:red;<0:yellow")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if color, ok := evalCond(rules, 150); !ok || color != "FF0000" {
+		t.Errorf("evalCond(150) = (%q, %v), want (FF0000, true)", color, ok)
+	}
+	if color, ok := evalCond(rules, -5); !ok || color != "FFFF00" {
+		t.Errorf("evalCond(-5) = (%q, %v), want (FFFF00, true)", color, ok)
+	}
+	if _, ok := evalCond(rules, 50); ok {
+		t.Error("evalCond(50) should not match any rule")
+	}
+	if _, ok := evalCond(rules, "not a number"); ok {
+		t.Error("evalCond of a non-numeric value should not match")
+	}
+}
+
+type styleTestRow struct {
+	Name  string `xls:"order=1,heading=Name"`
+	Value int    `xls:"order=2,heading=Value,cond=\">100:red;<0:yellow\""`
+}
+
+func TestWriteToSheetWithOptionsStyling(t *testing.T) {
+	rows := []styleTestRow{
+		{Name: "a", Value: 150},
+		{Name: "b", Value: -5},
+		{Name: "c", Value: 50},
+	}
+
+	file := xlsx.NewFile()
+	sheet, err := file.AddSheet("S")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := WriteOptions{BoldHeader: true, AutoFilter: true, FreezeHeader: true}
+	if err := WriteToSheetWithOptions(sheet, rows, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if !sheet.Rows[0].Cells[0].GetStyle().Font.Bold {
+		t.Error("header cell should be bold")
+	}
+	if sheet.AutoFilter == nil || sheet.AutoFilter.BottomRightCell != "B4" {
+		t.Errorf("autofilter = %+v, want range ending at B4", sheet.AutoFilter)
+	}
+	if len(sheet.SheetViews) == 0 || sheet.SheetViews[0].Pane == nil {
+		t.Error("expected a frozen pane from FreezeHeader")
+	}
+
+	if got := sheet.Rows[1].Cells[1].GetStyle().Fill.FgColor; got != "FF0000" {
+		t.Errorf("row 1 Value fill = %q, want FF0000", got)
+	}
+	if got := sheet.Rows[2].Cells[1].GetStyle().Fill.FgColor; got != "FFFF00" {
+		t.Errorf("row 2 Value fill = %q, want FFFF00", got)
+	}
+}
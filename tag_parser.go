@@ -0,0 +1,139 @@
+package xlsx_tags
+
+// Tokenizer for the xls struct tag grammar: a comma-separated list of
+// key=value pairs (order=1,heading=Total), plus bare boolean keys
+// (wrap). Values may be double-quoted to embed commas, colons or
+// non-ASCII text verbatim, e.g. heading="Qty, Total". Inside a quoted
+// value, \" and \\ are the only recognised escapes.
+//
+// This replaces an earlier implementation built on one regexp per tag
+// option, which silently misparsed commas inside format=, headings with
+// colons, and backslashes.
+
+import (
+	"fmt"
+	"strings"
+)
+
+// knownTagKeys enumerates every supported xls tag option. parseTag fails
+// on anything else so a misspelled key is caught at parse time instead
+// of silently doing nothing.
+var knownTagKeys = map[string]bool{
+	"order":   true,
+	"heading": true,
+	"format":  true,
+	"numFmt":  true,
+	"formula": true,
+	"width":   true,
+	"align":   true,
+	"color":   true,
+	"wrap":    true,
+	"cond":    true,
+}
+
+// parseTag tokenizes tag and returns its options as a key-to-value map.
+// A bare key with no '=' (such as wrap) is present in the map with an
+// empty value. Returns an error naming the offending key or byte offset
+// on malformed input.
+func parseTag(tag string) (map[string]string, error) {
+	tokens, err := tokenizeTag(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(tokens))
+	for _, tok := range tokens {
+		if !knownTagKeys[tok.key] {
+			return nil, fmt.Errorf("xls tag %q: unknown option %q at position %d", tag, tok.key, tok.pos)
+		}
+		values[tok.key] = tok.value
+	}
+	return values, nil
+}
+
+// tagToken is one key[=value] pair parsed out of a struct tag.
+type tagToken struct {
+	key   string
+	value string
+	pos   int // byte offset of the token within the original tag
+}
+
+func tokenizeTag(tag string) ([]tagToken, error) {
+	var tokens []tagToken
+	i := 0
+	for i < len(tag) {
+		start := i
+
+		eq := strings.IndexByte(tag[i:], '=')
+		comma := strings.IndexByte(tag[i:], ',')
+
+		// A bare key (e.g. "wrap") has no '=' before its terminating comma.
+		if eq == -1 || (comma != -1 && comma < eq) {
+			end := comma
+			if end == -1 {
+				end = len(tag) - i
+			}
+			key := strings.TrimSpace(tag[i : i+end])
+			if key != "" {
+				tokens = append(tokens, tagToken{key: key, pos: start})
+			}
+			i += end + 1
+			continue
+		}
+
+		key := strings.TrimSpace(tag[i : i+eq])
+		i += eq + 1
+
+		if i < len(tag) && tag[i] == '"' {
+			value, consumed, err := readQuoted(tag[i:])
+			if err != nil {
+				return nil, fmt.Errorf("xls tag %q: %v (position %d)", tag, err, i)
+			}
+			tokens = append(tokens, tagToken{key: key, value: value, pos: start})
+			i += consumed
+			if i < len(tag) && tag[i] == ',' {
+				i++
+			}
+			continue
+		}
+
+		comma = strings.IndexByte(tag[i:], ',')
+		end := comma
+		if end == -1 {
+			end = len(tag) - i
+		}
+		value := strings.TrimSpace(tag[i : i+end])
+		tokens = append(tokens, tagToken{key: key, value: value, pos: start})
+		i += end + 1
+	}
+	return tokens, nil
+}
+
+// readQuoted reads a double-quoted value starting at s[0] == '"'. It
+// unescapes \" and \\ and returns the value plus the number of bytes of
+// s consumed, including both quotes.
+func readQuoted(s string) (value string, consumed int, err error) {
+	var b strings.Builder
+	i := 1
+	for i < len(s) {
+		switch s[i] {
+		case '\\':
+			if i+1 >= len(s) {
+				return "", 0, fmt.Errorf("dangling escape in quoted value")
+			}
+			if s[i+1] == '"' || s[i+1] == '\\' {
+				b.WriteByte(s[i+1])
+				i += 2
+			} else {
+				b.WriteByte('\\')
+				i++
+			}
+		case '"':
+			return b.String(), i + 1, nil
+		default:
+			b.WriteByte(s[i])
+			i++
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated quoted value")
+}
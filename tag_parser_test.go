@@ -0,0 +1,75 @@
+package xlsx_tags
+
+import "testing"
+
+func TestParseTagQuotedValuesWithCommasAndEscapes(t *testing.T) {
+	opt, err := parseOptFromTag(`order=1,heading="Qty, Total \"net\"",numFmt="#,##0.00"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `Qty, Total "net"`; opt.heading != want {
+		t.Errorf("heading = %q, want %q", opt.heading, want)
+	}
+	if want := "#,##0.00"; opt.numFmt != want {
+		t.Errorf("numFmt = %q, want %q", opt.numFmt, want)
+	}
+}
+
+func TestParseTagUnicodeHeading(t *testing.T) {
+	opt, err := parseOptFromTag(`order=1,heading=Cantidad €/día`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Cantidad €/día"; opt.heading != want {
+		t.Errorf("heading = %q, want %q", opt.heading, want)
+	}
+}
+
+func TestParseTagQuotedHeadingWithColon(t *testing.T) {
+	opt, err := parseOptFromTag(`order=1,heading="Total: net"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Total: net"; opt.heading != want {
+		t.Errorf("heading = %q, want %q", opt.heading, want)
+	}
+}
+
+func TestParseTagUnknownKeyFailsLoudly(t *testing.T) {
+	_, err := parseOptFromTag(`order=1,heading=Total,bogus=1`)
+	if err == nil {
+		t.Fatal("expected an error for an unknown tag option")
+	}
+}
+
+func TestParseTagBareWrapFlag(t *testing.T) {
+	opt, err := parseOptFromTag(`order=1,heading=Notes,wrap`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opt.wrap {
+		t.Error("expected wrap to be true")
+	}
+}
+
+func TestHasFormattingSemantics(t *testing.T) {
+	withFormat := parseOpts{format: "2006-01-02"}
+	if !withFormat.hasFormatting() {
+		t.Error("hasFormatting() should be true when format is set")
+	}
+
+	without := parseOpts{}
+	if without.hasFormatting() {
+		t.Error("hasFormatting() should be false when format is empty")
+	}
+}
+
+func TestParseOptFromTagBackwardCompatible(t *testing.T) {
+	opt, err := parseOptFromTag("order=2,heading=Price,format=%0.2f")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opt.order != 2 || opt.heading != "Price" || opt.format != "%0.2f" {
+		t.Errorf("got %+v", opt)
+	}
+}